@@ -0,0 +1,338 @@
+package main
+
+import (
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a single cached HTTP response: its status code, headers, and
+// body, as captured by CacheMiddleware.
+type Entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Cache stores Entry values keyed by an opaque string produced by
+// CacheOptions.KeyFunc. Implementations are responsible for expiring
+// entries after the ttl passed to Set.
+type Cache interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheOptions controls what CacheMiddleware caches and how it keys
+// entries.
+type CacheOptions struct {
+	// TTL is how long an entry stays valid after being cached. Zero
+	// means entries never expire on their own.
+	TTL time.Duration
+
+	// Methods lists the request methods eligible for caching. Defaults
+	// to GET and HEAD.
+	Methods []string
+
+	// StatusCodes lists the response status codes eligible for
+	// caching. Defaults to all 2xx codes.
+	StatusCodes []int
+
+	// KeyFunc derives the cache key for a request. Defaults to a key
+	// built from method, path and query, refined by the values of any
+	// request headers a prior response for the same path listed in its
+	// Vary header.
+	KeyFunc func(r *http.Request) string
+}
+
+func (o *CacheOptions) cacheableMethod(method string) bool {
+	methods := o.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *CacheOptions) cacheableStatus(status int) bool {
+	if len(o.StatusCodes) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, code := range o.StatusCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// lruCache is the default in-memory Cache implementation: a bounded LRU
+// keyed on entry count and total body bytes, with per-entry TTL.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	numBytes   int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type lruItem struct {
+	key       string
+	entry     Entry
+	expires   time.Time
+	hasExpiry bool
+}
+
+// NewLRUCache returns a Cache that evicts the least-recently-used entry
+// once maxEntries entries or maxBytes bytes of cached bodies (whichever
+// comes first) would otherwise be exceeded. A zero limit disables that
+// particular bound.
+func NewLRUCache(maxEntries, maxBytes int) Cache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	item := el.Value.(*lruItem)
+	if item.hasExpiry && time.Now().After(item.expires) {
+		c.removeElement(el)
+		return Entry{}, false
+	}
+	c.ll.MoveToFront(el)
+	return item.entry, true
+}
+
+func (c *lruCache) Set(key string, entry Entry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	item := &lruItem{key: key, entry: entry}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+		item.hasExpiry = true
+	}
+	el := c.ll.PushFront(item)
+	c.items[key] = el
+	c.numBytes += len(entry.Body)
+
+	for (c.maxEntries > 0 && c.ll.Len() > c.maxEntries) || (c.maxBytes > 0 && c.numBytes > c.maxBytes) {
+		back := c.ll.Back()
+		if back == nil {
+			break
+		}
+		c.removeElement(back)
+	}
+}
+
+func (c *lruCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// removeElement drops el from both the list and the index. Callers must
+// hold c.mu.
+func (c *lruCache) removeElement(el *list.Element) {
+	item := el.Value.(*lruItem)
+	c.ll.Remove(el)
+	delete(c.items, item.key)
+	c.numBytes -= len(item.entry.Body)
+}
+
+// cacheEntryWriter wraps a statusWriter to additionally capture the body
+// written by a handler, so CacheMiddleware can store and later replay the
+// full response. Embedding statusWriter (rather than re-implementing
+// status capture and Flush/Hijack/Push forwarding here) is what lets
+// CacheMiddleware sit in front of WebSockets, SSE and HTTP/2 push just
+// like NewLoggingMiddleware does.
+type cacheEntryWriter struct {
+	*statusWriter
+	body []byte
+}
+
+func (w *cacheEntryWriter) Write(b []byte) (int, error) {
+	w.body = append(w.body, b...)
+	return w.statusWriter.Write(b)
+}
+
+// varyIndex remembers, per base cache key, which request headers the last
+// response for that key varied on, so subsequent lookups can fold their
+// values into the key before checking the Cache.
+type varyIndex struct {
+	mu    sync.Mutex
+	names map[string][]string
+}
+
+func newVaryIndex() *varyIndex {
+	return &varyIndex{names: make(map[string][]string)}
+}
+
+func (v *varyIndex) get(baseKey string) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.names[baseKey]
+}
+
+func (v *varyIndex) set(baseKey string, vary string) {
+	if vary == "" {
+		return
+	}
+	names := strings.Split(vary, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.names[baseKey] = names
+}
+
+// CacheMiddleware returns middleware that serves cached responses from
+// cache and stores eligible responses in it, per opts, remembering cache
+// as s's cache so Server.InvalidateCache can purge it later. Unlike the
+// naive path-only cache it replaces, it captures status code and headers
+// (not just the body), respects the request method and response status,
+// expires entries after opts.TTL, keys on the response's Vary header, and
+// honors Cache-Control: no-store/private on responses and no-cache on
+// requests.
+func (s *Server) CacheMiddleware(cache Cache, opts CacheOptions) Middleware {
+	s.cache = cache
+	vary := newVaryIndex()
+
+	baseKey := func(r *http.Request) string {
+		return r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+	}
+
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(r *http.Request) string {
+			key := baseKey(r)
+			var b strings.Builder
+			b.WriteString(key)
+			for _, name := range vary.get(key) {
+				b.WriteString("|")
+				b.WriteString(name)
+				b.WriteString("=")
+				b.WriteString(r.Header.Get(name))
+			}
+			return b.String()
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cacheable := opts.cacheableMethod(r.Method) && !hasCacheControlDirective(r.Header, "no-cache")
+
+			if cacheable {
+				if entry, ok := cache.Get(keyFunc(r)); ok {
+					for name, values := range entry.Header {
+						for _, v := range values {
+							w.Header().Add(name, v)
+						}
+					}
+					w.WriteHeader(entry.StatusCode)
+					w.Write(entry.Body)
+					return
+				}
+			}
+
+			rw := &cacheEntryWriter{statusWriter: &statusWriter{ResponseWriter: w}}
+			next.ServeHTTP(rw, r)
+
+			if !cacheable || !opts.cacheableStatus(rw.status) {
+				return
+			}
+			if hasCacheControlDirective(rw.Header(), "no-store") || hasCacheControlDirective(rw.Header(), "private") {
+				return
+			}
+
+			vary.set(baseKey(r), rw.Header().Get("Vary"))
+			cache.Set(keyFunc(r), Entry{
+				StatusCode: rw.status,
+				Header:     rw.Header().Clone(),
+				Body:       rw.body,
+			}, opts.TTL)
+		})
+	}
+}
+
+func hasCacheControlDirective(h http.Header, directive string) bool {
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(part), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidatingCache is implemented by caches that can enumerate their
+// keys, letting InvalidateCache purge by prefix without widening the
+// Cache interface itself. The default lruCache implements it.
+type invalidatingCache interface {
+	Cache
+	Keys() []string
+}
+
+func (c *lruCache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InvalidateCache purges every entry in s's cache whose key was cached
+// for a path under pathPrefix. It is a no-op if the server has no cache
+// configured, or if that cache can't enumerate its keys.
+func (s *Server) InvalidateCache(pathPrefix string) {
+	if s.cache == nil {
+		return
+	}
+	ic, ok := s.cache.(invalidatingCache)
+	if !ok {
+		return
+	}
+	for _, key := range ic.Keys() {
+		if keyPathPrefix(key, pathPrefix) {
+			s.cache.Delete(key)
+		}
+	}
+}
+
+// keyPathPrefix reports whether key (built as "METHOD path?query[|Vary
+// pairs]") was cached for a path equal to, or nested under, pathPrefix. A
+// raw strings.HasPrefix would also match "/users/5" against pathPrefix
+// "/user", so the prefix must end at a "/" boundary (or match exactly).
+func keyPathPrefix(key, pathPrefix string) bool {
+	fields := strings.SplitN(key, " ", 2)
+	if len(fields) != 2 {
+		return false
+	}
+	path := strings.SplitN(fields[1], "?", 2)[0]
+	return path == pathPrefix || strings.HasPrefix(path, strings.TrimSuffix(pathPrefix, "/")+"/")
+}
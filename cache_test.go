@@ -0,0 +1,185 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheMiddlewareHitAndMiss(t *testing.T) {
+	server := NewServer("8080")
+	hits := 0
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{TTL: time.Minute}))
+	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/", nil)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Body.String() != "hello" {
+		t.Fatalf("Expected body 'hello', got '%s'", rec.Body.String())
+	}
+
+	rec2 := httptest.NewRecorder()
+	server.router.ServeHTTP(rec2, req)
+	if rec2.Body.String() != "hello" {
+		t.Fatalf("Expected cached body 'hello', got '%s'", rec2.Body.String())
+	}
+
+	if hits != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", hits)
+	}
+}
+
+func TestCacheMiddlewareHonorsNoStore(t *testing.T) {
+	server := NewServer("8080")
+	hits := 0
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{}))
+	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/", nil)
+	server.router.ServeHTTP(httptest.NewRecorder(), req)
+	server.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if hits != 2 {
+		t.Errorf("Expected handler to run twice for no-store response, ran %d times", hits)
+	}
+}
+
+func TestCacheMiddlewareForwardsHijack(t *testing.T) {
+	server := NewServer("8080")
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{}))
+	server.AddRoute("/ws", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Errorf("Expected CacheMiddleware's response writer to implement http.Hijacker")
+		}
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/ws", nil)
+	server.router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestInvalidateCache(t *testing.T) {
+	server := NewServer("8080")
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{}))
+	hits := 0
+	server.AddRoute("/users/5", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("user"))
+	})
+	server.AddRoute("/user", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("user-root"))
+	})
+
+	usersReq := httptest.NewRequest("GET", "http://localhost:8080/users/5", nil)
+	userReq := httptest.NewRequest("GET", "http://localhost:8080/user", nil)
+	server.router.ServeHTTP(httptest.NewRecorder(), usersReq)
+	server.router.ServeHTTP(httptest.NewRecorder(), userReq)
+	if hits != 2 {
+		t.Fatalf("Expected both routes to be cached after one request each, handler ran %d times", hits)
+	}
+
+	server.InvalidateCache("/user")
+
+	server.router.ServeHTTP(httptest.NewRecorder(), usersReq)
+	if hits != 2 {
+		t.Errorf("Expected InvalidateCache(\"/user\") not to purge the unrelated '/users/5' entry, handler ran %d times", hits)
+	}
+
+	server.router.ServeHTTP(httptest.NewRecorder(), userReq)
+	if hits != 3 {
+		t.Errorf("Expected InvalidateCache(\"/user\") to purge the '/user' entry itself, handler ran %d times", hits)
+	}
+}
+
+func TestLRUCacheTTLExpiry(t *testing.T) {
+	cache := NewLRUCache(10, 1<<20)
+	cache.Set("k", Entry{StatusCode: 200, Body: []byte("v")}, time.Millisecond)
+
+	if _, ok := cache.Get("k"); !ok {
+		t.Fatal("Expected entry to be present immediately after Set")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.Get("k"); ok {
+		t.Error("Expected entry to have expired after its TTL elapsed")
+	}
+}
+
+func TestLRUCacheEvictsByMaxEntries(t *testing.T) {
+	cache := NewLRUCache(2, 0)
+	cache.Set("a", Entry{StatusCode: 200}, 0)
+	cache.Set("b", Entry{StatusCode: 200}, 0)
+	cache.Set("c", Entry{StatusCode: 200}, 0)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected least-recently-used entry 'a' to be evicted")
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("Expected entry 'b' to still be cached")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("Expected entry 'c' to still be cached")
+	}
+}
+
+func TestLRUCacheEvictsByMaxBytes(t *testing.T) {
+	cache := NewLRUCache(0, 10)
+	cache.Set("a", Entry{StatusCode: 200, Body: make([]byte, 6)}, 0)
+	cache.Set("b", Entry{StatusCode: 200, Body: make([]byte, 6)}, 0)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected entry 'a' to be evicted once total body bytes exceeded maxBytes")
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("Expected entry 'b' to still be cached")
+	}
+}
+
+func TestCacheMiddlewareVariesByHeader(t *testing.T) {
+	server := NewServer("8080")
+	hits := 0
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{}))
+	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	})
+
+	enReq := httptest.NewRequest("GET", "http://localhost:8080/", nil)
+	enReq.Header.Set("Accept-Language", "en")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, enReq)
+	if rec.Body.String() != "en" {
+		t.Fatalf("Expected body 'en', got '%s'", rec.Body.String())
+	}
+
+	frReq := httptest.NewRequest("GET", "http://localhost:8080/", nil)
+	frReq.Header.Set("Accept-Language", "fr")
+	rec2 := httptest.NewRecorder()
+	server.router.ServeHTTP(rec2, frReq)
+	if rec2.Body.String() != "fr" {
+		t.Fatalf("Expected Vary header to bypass the 'en' cache entry for a different Accept-Language, got '%s'", rec2.Body.String())
+	}
+
+	if hits != 2 {
+		t.Errorf("Expected handler to run once per distinct Accept-Language, ran %d times", hits)
+	}
+
+	rec3 := httptest.NewRecorder()
+	server.router.ServeHTTP(rec3, enReq)
+	if hits != 2 {
+		t.Errorf("Expected the repeated 'en' request to hit the cache, handler ran %d times", hits)
+	}
+}
+
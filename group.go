@@ -0,0 +1,39 @@
+package main
+
+import "net/http"
+
+// Group scopes a set of middleware and a path prefix to a subtree of
+// routes, so e.g. auth middleware can be applied to "/api/*" without
+// touching "/static". Build one with Server.Group.
+type Group struct {
+	server     *Server
+	prefix     string
+	middleware []Middleware
+}
+
+// Group returns a *Group whose routes are registered under prefix and
+// wrapped in mws, in addition to the server's global middleware.
+func (s *Server) Group(prefix string, mws ...Middleware) *Group {
+	return &Group{server: s, prefix: prefix, middleware: mws}
+}
+
+// Group returns a nested group, appending prefix to g's own prefix and mws
+// to g's own middleware.
+func (g *Group) Group(prefix string, mws ...Middleware) *Group {
+	middleware := make([]Middleware, 0, len(g.middleware)+len(mws))
+	middleware = append(middleware, g.middleware...)
+	middleware = append(middleware, mws...)
+	return &Group{server: g.server, prefix: g.prefix + prefix, middleware: middleware}
+}
+
+// AddRoute registers handler for prefix+path regardless of HTTP method,
+// wrapped in the group's middleware.
+func (g *Group) AddRoute(path string, handler http.HandlerFunc) {
+	g.AddRouteMethod(anyMethod, path, handler)
+}
+
+// AddRouteMethod registers handler for prefix+path, scoped to method and
+// wrapped in the group's middleware.
+func (g *Group) AddRouteMethod(method, path string, handler http.HandlerFunc) {
+	g.server.registerRoute(method, g.prefix+path, handler, g.middleware, nil)
+}
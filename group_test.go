@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroupScopesMiddleware(t *testing.T) {
+	server := NewServer("8080")
+
+	tagged := func(tag string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tag)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	api := server.Group("/api", tagged("api,"))
+	api.AddRoute("/ping", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "pong")
+	})
+	server.AddRoute("/ping", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "pong")
+	})
+
+	apiRec := httptest.NewRecorder()
+	server.router.ServeHTTP(apiRec, httptest.NewRequest("GET", "http://localhost:8080/api/ping", nil))
+	if apiRec.Body.String() != "api,pong" {
+		t.Errorf("Expected 'api,pong', got '%s'", apiRec.Body.String())
+	}
+
+	rootRec := httptest.NewRecorder()
+	server.router.ServeHTTP(rootRec, httptest.NewRequest("GET", "http://localhost:8080/ping", nil))
+	if rootRec.Body.String() != "pong" {
+		t.Errorf("Expected 'pong' with no group middleware applied, got '%s'", rootRec.Body.String())
+	}
+}
+
+func TestAddRouteWithAppliesPerRouteMiddleware(t *testing.T) {
+	server := NewServer("8080")
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "auth,")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	server.AddRouteWith("/secure", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secure")
+	}, auth)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/secure", nil))
+	if rec.Body.String() != "auth,secure" {
+		t.Errorf("Expected 'auth,secure', got '%s'", rec.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// LogFormat selects the line format produced by NewLoggingMiddleware.
+type LogFormat int
+
+const (
+	// LogCommon renders one Common Log Format-style line per request.
+	LogCommon LogFormat = iota
+	// LogJSON renders one JSON object per request.
+	LogJSON
+)
+
+// LoggingOptions configures NewLoggingMiddleware.
+type LoggingOptions struct {
+	// Format selects the line format. Defaults to LogCommon.
+	Format LogFormat
+	// Output is where log lines are written. Defaults to os.Stdout.
+	Output io.Writer
+	// ClockSkewSafe measures request duration with a monotonic clock
+	// reading (time.Since) instead of subtracting two wall-clock
+	// timestamps, avoiding a negative duration if the system clock is
+	// adjusted mid-request.
+	ClockSkewSafe bool
+}
+
+// NewLoggingMiddleware returns access-log middleware that logs after the
+// handler runs, including status code, response bytes and duration -
+// unlike LoggingMiddleware, which only logs the method and path at entry.
+func NewLoggingMiddleware(opts LoggingOptions) Middleware {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+			start := time.Now()
+
+			next.ServeHTTP(sw, r)
+
+			var duration time.Duration
+			if opts.ClockSkewSafe {
+				duration = time.Since(start)
+			} else {
+				duration = time.Now().Round(0).Sub(start.Round(0))
+			}
+
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			switch opts.Format {
+			case LogJSON:
+				writeJSONAccessLog(out, r, status, sw.bytes, duration)
+			default:
+				writeCommonAccessLog(out, r, status, sw.bytes, duration)
+			}
+		})
+	}
+}
+
+func writeCommonAccessLog(out io.Writer, r *http.Request, status, bytes int, duration time.Duration) {
+	fmt.Fprintf(out, "%s - - [%s] \"%s %s %s\" %d %d %s\n",
+		r.RemoteAddr,
+		time.Now().Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.URL.RequestURI(), r.Proto,
+		status, bytes, duration)
+}
+
+// accessLogRecord is the JSON shape written by writeJSONAccessLog.
+type accessLogRecord struct {
+	Time     string `json:"time"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Status   int    `json:"status"`
+	Bytes    int    `json:"bytes"`
+	Duration string `json:"duration"`
+	RemoteIP string `json:"remote_ip"`
+}
+
+func writeJSONAccessLog(out io.Writer, r *http.Request, status, bytes int, duration time.Duration) {
+	record := accessLogRecord{
+		Time:     time.Now().Format(time.RFC3339),
+		Method:   r.Method,
+		Path:     r.URL.RequestURI(),
+		Status:   status,
+		Bytes:    bytes,
+		Duration: duration.String(),
+		RemoteIP: r.RemoteAddr,
+	}
+	if b, err := json.Marshal(record); err == nil {
+		out.Write(append(b, '\n'))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggingMiddlewareCommonFormat(t *testing.T) {
+	var buf bytes.Buffer
+	server := NewServer("8080")
+	server.AddMiddleware(NewLoggingMiddleware(LoggingOptions{Output: &buf}))
+	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hi"))
+	})
+
+	server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/", nil))
+
+	line := buf.String()
+	if !strings.Contains(line, "\"GET / HTTP/1.1\" 201 2") {
+		t.Errorf("Expected common log line with status 201 and 2 bytes, got %q", line)
+	}
+}
+
+func TestLoggingMiddlewareJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	server := NewServer("8080")
+	server.AddMiddleware(NewLoggingMiddleware(LoggingOptions{Output: &buf, Format: LogJSON}))
+	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	server.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/", nil))
+
+	var record accessLogRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected valid JSON log line, got error: %v (line: %q)", err, buf.String())
+	}
+	if record.Status != http.StatusOK || record.Bytes != 5 {
+		t.Errorf("Expected status 200 and 5 bytes, got status %d bytes %d", record.Status, record.Bytes)
+	}
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,10 +14,28 @@ import (
 
 // Server struct - this struct will hold routes, middleware, cache, and configuration.
 type Server struct {
-	mux          *http.ServeMux
-	middleware   []Middleware
-	cache        sync.Map
-	port         string
+	router     *router
+	middleware []Middleware
+	cache      Cache
+	port       string
+
+	// TLSConfig, ReadHeaderTimeout and IdleTimeout mirror the matching
+	// fields on http.Server and are applied by Start, StartTLS and
+	// StartAutoTLS. Zero values fall back to http.Server's own defaults.
+	TLSConfig         *tls.Config
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+
+	// AutocertCacheDir is where StartAutoTLS persists certificates
+	// obtained from Let's Encrypt. Defaults to "certs" when empty.
+	AutocertCacheDir string
+
+	// wsMu guards wsConns, the set of currently active websocket
+	// connections registered via AddWebSocket. wsWG lets run() wait for
+	// them to finish closing during a graceful shutdown.
+	wsMu    sync.Mutex
+	wsConns map[*WSConn]struct{}
+	wsWG    sync.WaitGroup
 }
 
 // Middleware - middleware function type for modifying HTTP requests or responses.
@@ -25,47 +43,92 @@ type Middleware func(http.Handler) http.Handler
 
 // NewServer function - initializing a new Server with a specified port.
 func NewServer(port string) *Server {
-	// Using http.ServeMux to manage routing paths and handlers.
+	// Using a trie-based router to manage routing paths and handlers.
 	return &Server{
-		mux:        http.NewServeMux(),
+		router:     newRouter(),
 		port:       port,
 		middleware: []Middleware{}, // Empty middleware list to start.
 	}
 }
 
+// AddRoute registers handler for path regardless of HTTP method. path may
+// contain "{param}" segments and a trailing "*" wildcard; use
+// AddRouteMethod to scope a route to a specific method.
 func (s *Server) AddRoute(path string, handler http.HandlerFunc) {
-    // So, I want to set up this finalHandler as an http.Handler. Starting with the handler passed in.
-    var finalHandler http.Handler = handler 
-
-    // Now, let’s apply each middleware in order
-    for _, mw := range s.middleware {
-        // Wrapping the handler in middleware layer by layer
-        finalHandler = mw(finalHandler)
-    }
-    
-    // Finally, attaching the fully wrapped handler to our ServeMux
-    s.mux.Handle(path, finalHandler)
+	s.AddRouteMethod(anyMethod, path, handler)
 }
 
+// AddRouteMethod registers handler for path, but only for requests using
+// method (e.g. "GET", "POST"). Requests to path with a different method
+// receive a 405 with an Allow header listing the methods that are
+// registered.
+func (s *Server) AddRouteMethod(method, path string, handler http.HandlerFunc) {
+	s.registerRoute(method, path, handler, nil, nil)
+}
+
+// AddRouteWith registers handler for path (any method), wrapping it in mws
+// in addition to the server's global middleware. Use this to apply
+// middleware to a single route without reaching for Group.
+func (s *Server) AddRouteWith(path string, handler http.HandlerFunc, mws ...Middleware) {
+	s.registerRoute(anyMethod, path, handler, nil, mws)
+}
+
+// registerRoute wraps handler in the effective middleware chain -
+// s.middleware, then groupMW, then routeMW, outer to inner - and
+// registers it in the router.
+func (s *Server) registerRoute(method, path string, handler http.HandlerFunc, groupMW, routeMW []Middleware) {
+	finalHandler := chain(handler, s.middleware, groupMW, routeMW)
+	s.router.add(method, path, finalHandler)
+}
+
+// chain composes handler with the given middleware chains applied in
+// order, outer to inner: the first middleware of the first chain runs
+// first and the last middleware of the last chain runs immediately before
+// handler.
+func chain(handler http.Handler, chains ...[]Middleware) http.Handler {
+	var all []Middleware
+	for _, c := range chains {
+		all = append(all, c...)
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		handler = all[i](handler)
+	}
+	return handler
+}
 
 // AddMiddleware - Adds middleware to the Server struct’s middleware list.
 func (s *Server) AddMiddleware(mw Middleware) {
 	s.middleware = append(s.middleware, mw)
 }
 
+// newHTTPServer builds the *http.Server used by Start, StartTLS and
+// StartAutoTLS, applying the tunables exposed on Server.
+func (s *Server) newHTTPServer() *http.Server {
+	return &http.Server{
+		Addr:              ":" + s.port,
+		Handler:           s.router,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		IdleTimeout:       s.IdleTimeout,
+		TLSConfig:         s.TLSConfig,
+	}
+}
+
 // Start - Method to start the server with graceful shutdown handling.
 func (s *Server) Start() {
-	srv := &http.Server{
-		Addr:         ":" + s.port,
-		Handler:      s.mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-	}
+	srv := s.newHTTPServer()
+	s.run(srv, srv.ListenAndServe)
+}
 
+// run launches srv via listenAndServe in a goroutine, then blocks until an
+// interrupt signal is received and gracefully shuts srv down. Start,
+// StartTLS and StartAutoTLS all share this path.
+func (s *Server) run(srv *http.Server, listenAndServe func() error) {
 	// Launch server in a goroutine to allow graceful shutdown
 	go func() {
 		log.Printf("Server started on port %s", s.port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := listenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Could not listen on %s: %v\n", s.port, err)
 		}
 	}()
@@ -76,6 +139,12 @@ func (s *Server) Start() {
 	<-stop
 
 	log.Println("Shutting down the server...")
+
+	// srv.Shutdown doesn't touch hijacked connections, which is what a
+	// websocket connection becomes once upgraded - close them ourselves
+	// first so they aren't killed mid-frame.
+	s.closeWebSockets(5 * time.Second)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -86,56 +155,17 @@ func (s *Server) Start() {
 	log.Println("Server exiting")
 }
 
-// LoggingMiddleware - Middleware for logging requests.
-func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("Received %s request for %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-	})
-}
-
-// CacheMiddleware - Middleware for caching responses in sync.Map.
-func (s *Server) CacheMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if response exists in cache.
-		if val, ok := s.cache.Load(r.URL.Path); ok {
-			log.Printf("Cache hit for %s", r.URL.Path)
-			fmt.Fprint(w, val)
-			return
-		}
-
-		// If not cached, capture response for future use.
-		log.Printf("Cache miss for %s", r.URL.Path)
-		rw := &responseWriter{ResponseWriter: w}
-		next.ServeHTTP(rw, r)
-		s.cache.Store(r.URL.Path, rw.Body.String()) // Store response in cache.
-	})
-}
-
-// Custom responseWriter for caching.
-type responseWriter struct {
-	http.ResponseWriter
-	Body *bytes.Buffer
-}
-
-func (rw *responseWriter) Write(b []byte) (int, error) {
-	// Initialize buffer if Body is nil.
-	if rw.Body == nil {
-		rw.Body = &bytes.Buffer{}
-	}
-	rw.Body.Write(b) // Store written data in buffer for caching.
-	return rw.ResponseWriter.Write(b) // Write data to actual response.
-}
-
 // main function to set up server with routes and middleware.
 func main() {
 	server := NewServer("8080")
 
-	// Adding LoggingMiddleware to track each request.
-	server.AddMiddleware(LoggingMiddleware)
+	// Adding an access-log middleware to record status, bytes and duration for each request.
+	server.AddMiddleware(NewLoggingMiddleware(LoggingOptions{}))
 
-	// Adding CacheMiddleware to cache responses to repeated requests.
-	server.AddMiddleware(server.CacheMiddleware)
+	// Adding CacheMiddleware to cache responses to repeated GET/HEAD requests.
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(1000, 10<<20), CacheOptions{
+		TTL: time.Minute,
+	}))
 
 	// Adding a simple route to demonstrate response.
 	server.AddRoute("/", func(w http.ResponseWriter, r *http.Request) {
@@ -143,20 +173,8 @@ func main() {
 	})
 
 	// Static file handling route.
-	server.AddRoute("/static", http.FileServer(http.Dir("./static")).ServeHTTP)
+	server.AddStatic("/static", "./static", StaticOptions{})
 
 	// Start the server.
 	server.Start()
 }
-
-// StartTLS - Starts the server with TLS, providing HTTP/2 support.
-func (s *Server) StartTLS(certFile, keyFile string) {
-	srv := &http.Server{
-		Addr:         ":" + s.port,
-		Handler:      s.mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-	}
-	log.Printf("Server started with HTTPS on port %s", s.port)
-	log.Fatal(srv.ListenAndServeTLS(certFile, keyFile))
-}
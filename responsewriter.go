@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// statusWriter wraps an http.ResponseWriter to record the status code and
+// number of bytes written by a handler. It forwards http.Flusher,
+// http.Hijacker and http.Pusher to the underlying writer when supported,
+// so wrapping it doesn't break SSE, WebSockets, or HTTP/2 push.
+//
+// NewLoggingMiddleware uses it directly; CacheMiddleware embeds it in
+// cacheEntryWriter to additionally capture the response body.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements http.Flusher for streaming responses (e.g. SSE).
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker so protocols like WebSockets can take
+// over the underlying connection.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// Push implements http.Pusher for HTTP/2 server push.
+func (w *statusWriter) Push(target string, opts *http.PushOptions) error {
+	p, ok := w.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// contextKey is an unexported type to avoid collisions with context keys
+// set by other packages.
+type contextKey string
+
+// paramsContextKey is where the router stashes captured path parameters.
+const paramsContextKey contextKey = "pathParams"
+
+// anyMethod marks a route registered without a specific HTTP method (via
+// AddRoute), matching any method that has no more specific handler.
+const anyMethod = ""
+
+// wildcardParam is the key under which a trailing "*" catch-all segment's
+// matched suffix is stored.
+const wildcardParam = "*"
+
+// routeNode is a single node in the method-aware routing trie. A node is
+// either a literal segment, a "{param}" segment, or a "*" catch-all.
+type routeNode struct {
+	children      map[string]*routeNode
+	paramChild    *routeNode
+	paramName     string
+	wildcardChild *routeNode
+	handlers      map[string]http.Handler
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{children: make(map[string]*routeNode)}
+}
+
+// router is a trie-based HTTP request matcher that replaces http.ServeMux,
+// adding path parameters, wildcards, and per-method dispatch.
+type router struct {
+	root *routeNode
+}
+
+func newRouter() *router {
+	return &router{root: newRouteNode()}
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// add registers handler for method (or anyMethod for all methods) at
+// pattern. Segments of the form "{name}" capture a path parameter and "*"
+// matches the remainder of the path.
+func (rt *router) add(method, pattern string, handler http.Handler) {
+	node := rt.root
+	for _, seg := range splitPath(pattern) {
+		switch {
+		case seg == "*":
+			if node.wildcardChild == nil {
+				node.wildcardChild = newRouteNode()
+			}
+			node = node.wildcardChild
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			if node.paramChild == nil {
+				node.paramChild = newRouteNode()
+			}
+			node.paramName = strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}")
+			node = node.paramChild
+		default:
+			child, ok := node.children[seg]
+			if !ok {
+				child = newRouteNode()
+				node.children[seg] = child
+			}
+			node = child
+		}
+	}
+	if node.handlers == nil {
+		node.handlers = make(map[string]http.Handler)
+	}
+	node.handlers[method] = handler
+}
+
+// find walks segments looking for a terminal node with registered handlers,
+// collecting captured parameters into params as it goes.
+func (n *routeNode) find(segments []string, params map[string]string) (*routeNode, bool) {
+	if len(segments) == 0 {
+		if n.handlers != nil {
+			return n, true
+		}
+		return nil, false
+	}
+
+	seg, rest := segments[0], segments[1:]
+
+	if child, ok := n.children[seg]; ok {
+		if found, ok := child.find(rest, params); ok {
+			return found, true
+		}
+	}
+	if n.paramChild != nil {
+		params[n.paramName] = seg
+		if found, ok := n.paramChild.find(rest, params); ok {
+			return found, true
+		}
+		delete(params, n.paramName)
+	}
+	if n.wildcardChild != nil && n.wildcardChild.handlers != nil {
+		params[wildcardParam] = strings.Join(segments, "/")
+		return n.wildcardChild, true
+	}
+	return nil, false
+}
+
+// ServeHTTP implements http.Handler, matching r against the trie and
+// dispatching to the handler registered for r.Method, falling back to a
+// method-agnostic handler, and replying 405 with an Allow header when the
+// path matches but no handler covers the method.
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := splitPath(r.URL.Path)
+	params := make(map[string]string)
+	node, matched := rt.root.find(segments, params)
+	if !matched {
+		http.NotFound(w, r)
+		return
+	}
+
+	handler, ok := node.handlers[r.Method]
+	if !ok {
+		handler, ok = node.handlers[anyMethod]
+	}
+	if !ok {
+		allowed := make([]string, 0, len(node.handlers))
+		for m := range node.handlers {
+			if m != anyMethod {
+				allowed = append(allowed, m)
+			}
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(params) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsContextKey, params))
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// PathParam returns the value captured for name (e.g. "id" for a route
+// registered as "/users/{id}"), or "" if it wasn't captured. Use
+// PathParam(r, "*") to read a "*" catch-all suffix.
+func PathParam(r *http.Request, name string) string {
+	params, ok := r.Context().Value(paramsContextKey).(map[string]string)
+	if !ok {
+		return ""
+	}
+	return params[name]
+}
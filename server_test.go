@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,8 +18,8 @@ func TestServerResponse(t *testing.T) {
 	// ResponseRecorder captures the response for verification.
 	rec := httptest.NewRecorder()
 
-	// Serve HTTP request using Server's mux.
-	server.mux.ServeHTTP(rec, req)
+	// Serve HTTP request using Server's router.
+	server.router.ServeHTTP(rec, req)
 
 	// Checking if response status code is 200 OK.
 	if status := rec.Result().StatusCode; status != http.StatusOK {
@@ -31,3 +32,37 @@ func TestServerResponse(t *testing.T) {
 		t.Errorf("Expected response body '%s', got '%s'", expected, rec.Body.String())
 	}
 }
+
+func TestRoutePathParam(t *testing.T) {
+	server := NewServer("8080")
+	server.AddRouteMethod("GET", "/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, PathParam(r, "id"))
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/users/42", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if status := rec.Result().StatusCode; status != http.StatusOK {
+		t.Errorf("Expected status code 200, got %v", status)
+	}
+	if rec.Body.String() != "42" {
+		t.Errorf("Expected path param '42', got '%s'", rec.Body.String())
+	}
+}
+
+func TestRouteMethodNotAllowed(t *testing.T) {
+	server := NewServer("8080")
+	server.AddRouteMethod("GET", "/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest("POST", "http://localhost:8080/users/42", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if status := rec.Result().StatusCode; status != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code 405, got %v", status)
+	}
+	if allow := rec.Result().Header.Get("Allow"); allow != "GET" {
+		t.Errorf("Expected Allow header 'GET', got '%s'", allow)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StaticOptions configures Server.AddStatic.
+type StaticOptions struct {
+	// DirectoryListing enables http.FileServer's default directory
+	// listing for directories with no index.html. Defaults to off,
+	// which turns those requests into 404s instead.
+	DirectoryListing bool
+
+	// NotFoundPage, if set, is a file under dir served (with a 404
+	// status) instead of the default plain-text 404 body. Ignored when
+	// SPA is enabled.
+	NotFoundPage string
+
+	// Precompressed serves name+".br" or name+".gz" in place of name
+	// when the client's Accept-Encoding allows it and the compressed
+	// file exists.
+	Precompressed bool
+
+	// MaxAge sets "Cache-Control: max-age=<seconds>" per file
+	// extension (e.g. MaxAge[".js"] = time.Hour). Extensions without an
+	// entry get no Cache-Control header.
+	MaxAge map[string]time.Duration
+
+	// SPA serves dir/index.html for any request path that doesn't
+	// match a file under dir, so client-side routers can handle it.
+	SPA bool
+}
+
+// AddStatic serves the contents of dir under prefix, stripping prefix
+// from the request path first (server.AddRoute("/static",
+// http.FileServer(http.Dir("./static")).ServeHTTP) is broken exactly
+// because it skips that step, resolving requests to ./static/static/...).
+func (s *Server) AddStatic(prefix, dir string, opts StaticOptions) {
+	prefix = "/" + strings.Trim(prefix, "/")
+
+	fs := staticFileSystem{root: http.Dir(dir), listDirs: opts.DirectoryListing}
+	fileServer := http.StripPrefix(prefix, http.FileServer(fs))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		relPath := strings.TrimPrefix(r.URL.Path, prefix)
+
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+relPath)))); err != nil {
+			if opts.SPA {
+				serveWithCacheControl(w, r, http.StatusOK, filepath.Join(dir, "index.html"), "/index.html", opts.MaxAge)
+				return
+			}
+			if opts.NotFoundPage != "" {
+				serveWithCacheControl(w, r, http.StatusNotFound, filepath.Join(dir, opts.NotFoundPage), opts.NotFoundPage, opts.MaxAge)
+				return
+			}
+		}
+
+		if opts.Precompressed && servePrecompressed(w, r, dir, relPath) {
+			return
+		}
+
+		setCacheControl(w, relPath, opts.MaxAge)
+		fileServer.ServeHTTP(w, r)
+	}
+
+	// Register both prefix itself (e.g. "/static" or "/") and
+	// prefix+"/*" - the router's "*" wildcard only matches when there's
+	// at least one path segment past prefix, so without this the
+	// prefix's own root (bare GET "/" for an SPA, or GET "/static") would
+	// 404 before handler ever ran.
+	s.AddRoute(prefix, handler)
+	s.AddRoute(prefix+"/*", handler)
+}
+
+// serveWithCacheControl sets Cache-Control, then status, before serving
+// fullPath - setting a header after WriteHeader (which http.ServeFile
+// calls internally) silently drops it.
+func serveWithCacheControl(w http.ResponseWriter, r *http.Request, status int, fullPath, relPath string, maxAge map[string]time.Duration) {
+	setCacheControl(w, relPath, maxAge)
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+	http.ServeFile(w, r, fullPath)
+}
+
+func setCacheControl(w http.ResponseWriter, relPath string, maxAge map[string]time.Duration) {
+	if maxAge == nil {
+		return
+	}
+	if ttl, ok := maxAge[filepath.Ext(relPath)]; ok {
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(ttl.Seconds())))
+	}
+}
+
+// servePrecompressed serves dir/relPath+".br" or dir/relPath+".gz" in
+// place of dir/relPath when the client's Accept-Encoding allows it and
+// the compressed variant exists, reporting whether it did so.
+func servePrecompressed(w http.ResponseWriter, r *http.Request, dir, relPath string) bool {
+	accept := r.Header.Get("Accept-Encoding")
+	candidates := []struct {
+		suffix   string
+		encoding string
+	}{
+		{".br", "br"},
+		{".gz", "gzip"},
+	}
+
+	for _, c := range candidates {
+		if !strings.Contains(accept, c.encoding) {
+			continue
+		}
+		full := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+relPath))+c.suffix)
+		if _, err := os.Stat(full); err != nil {
+			continue
+		}
+		w.Header().Set("Content-Encoding", c.encoding)
+		w.Header().Set("Vary", "Accept-Encoding")
+		http.ServeFile(w, r, full)
+		return true
+	}
+	return false
+}
+
+// staticFileSystem wraps an http.FileSystem so that, unless listDirs is
+// set, opening a directory with no index.html reports it as not existing
+// instead of letting http.FileServer render a directory listing.
+type staticFileSystem struct {
+	root     http.FileSystem
+	listDirs bool
+}
+
+func (fs staticFileSystem) Open(name string) (http.File, error) {
+	f, err := fs.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fs.listDirs {
+		return f, nil
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return f, nil
+	}
+
+	index := strings.TrimSuffix(name, "/") + "/index.html"
+	if _, err := fs.root.Open(index); err != nil {
+		f.Close()
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return f, nil
+}
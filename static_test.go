@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddStaticStripsPrefix(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/static", dir, StaticOptions{})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/static/hello.txt", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "hi" {
+		t.Errorf("Expected body 'hi', got '%s'", rec.Body.String())
+	}
+}
+
+func TestAddStaticSPAFallback(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<app/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/", dir, StaticOptions{SPA: true})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/dashboard/settings", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "<app/>" {
+		t.Errorf("Expected SPA fallback body '<app/>', got '%s'", rec.Body.String())
+	}
+}
+
+func TestAddStaticSPAFallbackAtRoot(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<app/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/", dir, StaticOptions{SPA: true})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected bare GET / to hit the SPA fallback, got status %d", rec.Code)
+	}
+	if rec.Body.String() != "<app/>" {
+		t.Errorf("Expected SPA fallback body '<app/>', got '%s'", rec.Body.String())
+	}
+}
+
+func TestAddStaticBarePrefixServesIndex(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>index</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/static", dir, StaticOptions{})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/static", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected bare GET /static to serve dir/index.html, got status %d", rec.Code)
+	}
+	if rec.Body.String() != "<html>index</html>" {
+		t.Errorf("Expected index.html body, got '%s'", rec.Body.String())
+	}
+}
+
+func TestAddStaticDirectoryListingDisabledByDefault(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "file.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/static", dir, StaticOptions{})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "http://localhost:8080/static/sub/", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected directory listing to be disabled (404), got status %d", rec.Code)
+	}
+}
+
+func TestAddStaticNotFoundPageSetsCacheControl(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "404.html"), []byte("not found"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer("8080")
+	server.AddStatic("/static", dir, StaticOptions{
+		NotFoundPage: "/404.html",
+		MaxAge:       map[string]time.Duration{".html": time.Minute},
+	})
+
+	// httptest.NewRecorder doesn't surface a dropped header set after
+	// WriteHeader the way a real connection does, so this needs a real
+	// server round trip.
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/static/missing")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "max-age=60" {
+		t.Errorf("Expected Cache-Control 'max-age=60', got '%s'", got)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+)
+
+// StartTLS starts the server with TLS and HTTP/2 enabled, serving
+// certFile/keyFile, and shares Start's graceful-shutdown path.
+func (s *Server) StartTLS(certFile, keyFile string) {
+	srv := s.newHTTPServer()
+	if err := http2.ConfigureServer(srv, &http2.Server{}); err != nil {
+		log.Fatalf("Could not configure HTTP/2: %v", err)
+	}
+	s.run(srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// StartAutoTLS starts the server with TLS and HTTP/2 enabled, obtaining
+// certificates for hosts automatically from Let's Encrypt via autocert.
+// Certificates are cached in Server.AutocertCacheDir (default "certs").
+func (s *Server) StartAutoTLS(hosts ...string) {
+	cacheDir := s.AutocertCacheDir
+	if cacheDir == "" {
+		cacheDir = "certs"
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+
+	srv := s.newHTTPServer()
+	srv.TLSConfig = manager.TLSConfig()
+	if err := http2.ConfigureServer(srv, &http2.Server{}); err != nil {
+		log.Fatalf("Could not configure HTTP/2: %v", err)
+	}
+	s.run(srv, func() error {
+		return srv.ListenAndServeTLS("", "")
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// WSConn is a single accepted websocket connection, passed to the handler
+// registered via Server.AddWebSocket.
+type WSConn struct {
+	conn   *websocket.Conn
+	mu     sync.Mutex
+	closed bool
+}
+
+// ReadMessage blocks until a message arrives on the connection, or
+// returns an error (typically io.EOF once the peer disconnects).
+func (c *WSConn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	if err := websocket.Message.Receive(c.conn, &msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// WriteMessage sends msg as a single websocket message.
+func (c *WSConn) WriteMessage(msg []byte) error {
+	return websocket.Message.Send(c.conn, msg)
+}
+
+// Close sends a close frame and closes the underlying connection. It is
+// safe to call more than once.
+func (c *WSConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
+
+// AddWebSocket registers handler to run, in its own goroutine per
+// connection, for websocket upgrade requests to path. Active connections
+// are tracked so Server.Start's graceful shutdown can close them cleanly
+// instead of letting http.Server.Shutdown drop them mid-frame.
+func (s *Server) AddWebSocket(path string, handler func(*WSConn)) {
+	wsHandler := websocket.Handler(func(conn *websocket.Conn) {
+		wsConn := &WSConn{conn: conn}
+		s.trackWSConn(wsConn)
+		defer s.untrackWSConn(wsConn)
+		handler(wsConn)
+	})
+	s.router.add(anyMethod, path, chain(wsHandler, s.middleware))
+}
+
+func (s *Server) trackWSConn(c *WSConn) {
+	s.wsWG.Add(1)
+	s.wsMu.Lock()
+	if s.wsConns == nil {
+		s.wsConns = make(map[*WSConn]struct{})
+	}
+	s.wsConns[c] = struct{}{}
+	s.wsMu.Unlock()
+}
+
+func (s *Server) untrackWSConn(c *WSConn) {
+	s.wsMu.Lock()
+	delete(s.wsConns, c)
+	s.wsMu.Unlock()
+	s.wsWG.Done()
+}
+
+// closeWebSockets closes every active websocket connection and waits up
+// to timeout for their handler goroutines to finish.
+func (s *Server) closeWebSockets(timeout time.Duration) {
+	s.wsMu.Lock()
+	conns := make([]*WSConn, 0, len(s.wsConns))
+	for c := range s.wsConns {
+		conns = append(conns, c)
+	}
+	s.wsMu.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wsWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func wsURL(ts *httptest.Server, path string) string {
+	return "ws" + strings.TrimPrefix(ts.URL, "http") + path
+}
+
+func TestAddWebSocketEchoRoundTrip(t *testing.T) {
+	server := NewServer("0")
+	// Registering CacheMiddleware globally, the way main() does, is what
+	// exposed the Hijacker panic this test guards against.
+	server.AddMiddleware(server.CacheMiddleware(NewLRUCache(10, 1<<20), CacheOptions{}))
+	server.AddWebSocket("/ws", func(conn *WSConn) {
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(msg); err != nil {
+				return
+			}
+		}
+	})
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	ws, err := websocket.Dial(wsURL(ts, "/ws"), "", ts.URL)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer ws.Close()
+
+	if err := websocket.Message.Send(ws, []byte("hello")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var reply []byte
+	if err := websocket.Message.Receive(ws, &reply); err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if string(reply) != "hello" {
+		t.Errorf("Expected echo 'hello', got '%s'", reply)
+	}
+}
+
+func TestCloseWebSocketsClosesActiveConnections(t *testing.T) {
+	server := NewServer("0")
+	started := make(chan struct{})
+	closed := make(chan struct{})
+	server.AddWebSocket("/ws", func(conn *WSConn) {
+		close(started)
+		conn.ReadMessage() // blocks until the connection is closed
+		close(closed)
+	})
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	ws, err := websocket.Dial(wsURL(ts, "/ws"), "", ts.URL)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer ws.Close()
+
+	<-started
+	server.closeWebSockets(time.Second)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the handler's ReadMessage to unblock once closeWebSockets ran")
+	}
+}